@@ -4,12 +4,15 @@ import (
 	"archive/zip"
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -45,6 +48,46 @@ type step struct {
 	logs  string
 }
 
+var runFields = []string{
+	"id",
+	"name",
+	"workflow",
+	"event",
+	"status",
+	"conclusion",
+	"url",
+	"created_at",
+	"updated_at",
+	"run_attempt",
+	"head_branch",
+	"head_sha",
+	"actor",
+	"number",
+	"jobs",
+	"artifacts",
+	"annotations",
+}
+
+var jobFields = []string{
+	"id",
+	"name",
+	"status",
+	"conclusion",
+	"started_at",
+	"completed_at",
+	"url",
+	"runner",
+	"steps",
+}
+
+// viewFields is the full set of --json fields accepted by `gh run view`: the
+// run-level fields plus the job-only fields, since --job --json emits a
+// jobExport document instead of a runExport one. It's a superset rather than
+// two separately-validated lists so a single static registration at command
+// construction time (before we know whether --job was passed) covers both
+// shapes; ExportData simply omits whichever fields don't apply.
+var viewFields = append(append([]string{}, runFields...), "started_at", "completed_at", "runner", "steps")
+
 type ViewOptions struct {
 	HttpClient func() (*http.Client, error)
 	IO         *iostreams.IOStreams
@@ -58,6 +101,26 @@ type ViewOptions struct {
 	Log        bool
 	Web        bool
 
+	Latest   bool
+	Workflow string
+	Branch   string
+	Event    string
+	Status   string
+
+	Follow   bool
+	Interval int
+
+	FilterJob  string
+	FilterStep string
+	Grep       string
+	Context    int
+	FailedOnly bool
+
+	Failures bool
+	Tail     int
+
+	Exporter cmdutil.Exporter
+
 	Prompt bool
 
 	Now func() time.Time
@@ -69,6 +132,8 @@ func NewCmdView(f *cmdutil.Factory, runF func(*ViewOptions) error) *cobra.Comman
 		HttpClient: f.HttpClient,
 		Now:        time.Now,
 		Browser:    f.Browser,
+		Interval:   2,
+		Tail:       20,
 	}
 
 	cmd := &cobra.Command{
@@ -89,21 +154,47 @@ func NewCmdView(f *cmdutil.Factory, runF func(*ViewOptions) error) *cobra.Comman
 			# View the full log for a specific job
 			$ gh run view --log --job 456789
 
+			# Watch an in-progress run's logs until it completes
+			$ gh run view --log --follow 456789
+
 		  # Exit non-zero if a run failed
 		  $ gh run view 0451 -e && echo "run pending or passed"
+
+		  # View the latest run
+		  $ gh run view --latest
+
+		  # View the latest run for a given workflow and branch
+		  $ gh run view --workflow=ci.yml --branch=main --latest
+
+		  # View the latest completed run for a given workflow
+		  $ gh run view --workflow=ci.yml --status=completed --latest
+
+		  # Get the run as JSON, extracting the conclusion with jq
+		  $ gh run view 12345 --json conclusion --jq .conclusion
+
+		  # Show context for a failed run's failed steps without opening each job
+		  $ gh run view 12345 --failures
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// support `-R, --repo` override
 			opts.BaseRepo = f.BaseRepo
 
-			if len(args) == 0 && opts.JobID == "" {
+			if len(args) > 0 {
+				opts.RunID = args[0]
+			}
+
+			selectingLatest := opts.Latest || opts.Workflow != "" || opts.Branch != "" || opts.Event != "" || opts.Status != ""
+
+			if opts.RunID == "" && opts.JobID == "" && !selectingLatest {
 				if !opts.IO.CanPrompt() {
 					return &cmdutil.FlagError{Err: errors.New("run or job ID required when not running interactively")}
 				} else {
 					opts.Prompt = true
 				}
-			} else if len(args) > 0 {
-				opts.RunID = args[0]
+			}
+
+			if opts.RunID != "" && selectingLatest {
+				return &cmdutil.FlagError{Err: errors.New("specify a run ID or --latest/--workflow/--branch/--event/--status, not both")}
 			}
 
 			if opts.RunID != "" && opts.JobID != "" {
@@ -118,6 +209,34 @@ func NewCmdView(f *cmdutil.Factory, runF func(*ViewOptions) error) *cobra.Comman
 				return &cmdutil.FlagError{Err: errors.New("specify only one of --web or --log")}
 			}
 
+			if opts.Failures && (opts.Web || opts.Log) {
+				return &cmdutil.FlagError{Err: errors.New("specify only one of --web, --log, or --failures")}
+			}
+
+			if opts.Exporter != nil && (opts.Web || opts.Log || opts.Failures) {
+				return &cmdutil.FlagError{Err: errors.New("specify only one of --web, --log, --failures, or --json")}
+			}
+
+			if opts.Follow && !opts.Log {
+				return &cmdutil.FlagError{Err: errors.New("--follow requires --log")}
+			}
+
+			if opts.Interval < 1 {
+				return &cmdutil.FlagError{Err: errors.New("--interval must be at least 1 second")}
+			}
+
+			if !opts.Log && (opts.FilterJob != "" || opts.FilterStep != "" || opts.Grep != "" || opts.Context != 0 || opts.FailedOnly) {
+				return &cmdutil.FlagError{Err: errors.New("--filter-job, --filter-step, --grep, --context, and --failed-only require --log")}
+			}
+
+			if opts.Log && opts.JobID != "" && (opts.FilterJob != "" || opts.FilterStep != "" || opts.FailedOnly) {
+				return &cmdutil.FlagError{Err: errors.New("--filter-job, --filter-step, and --failed-only apply to a run's log, not a single --job log; use --grep instead")}
+			}
+
+			if opts.Tail < 1 {
+				return &cmdutil.FlagError{Err: errors.New("--tail must be at least 1")}
+			}
+
 			if runF != nil {
 				return runF(opts)
 			}
@@ -130,6 +249,21 @@ func NewCmdView(f *cmdutil.Factory, runF func(*ViewOptions) error) *cobra.Comman
 	cmd.Flags().StringVarP(&opts.JobID, "job", "j", "", "View a specific job ID from a run")
 	cmd.Flags().BoolVar(&opts.Log, "log", false, "View full log for either a run or specific job")
 	cmd.Flags().BoolVarP(&opts.Web, "web", "w", false, "Open run in the browser")
+	cmd.Flags().BoolVar(&opts.Latest, "latest", false, "View the latest run that matches the given filters")
+	cmd.Flags().StringVar(&opts.Workflow, "workflow", "", "Filter by workflow file name")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "Filter by branch")
+	cmd.Flags().StringVar(&opts.Event, "event", "", "Filter by event type")
+	cmd.Flags().StringVar(&opts.Status, "status", "", "Filter by run status (e.g. completed, in_progress, queued)")
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Watch a run or job log until it completes, printing new output as it is produced")
+	cmd.Flags().IntVar(&opts.Interval, "interval", 2, "Refresh interval in seconds when using --follow")
+	cmd.Flags().StringVar(&opts.FilterJob, "filter-job", "", "Only show log output for jobs matching this glob pattern")
+	cmd.Flags().StringVar(&opts.FilterStep, "filter-step", "", "Only show log output for steps matching this glob pattern")
+	cmd.Flags().StringVar(&opts.Grep, "grep", "", "Only show log lines matching this regular expression")
+	cmd.Flags().IntVar(&opts.Context, "context", 0, "Number of lines of context to show around each --grep match")
+	cmd.Flags().BoolVar(&opts.FailedOnly, "failed-only", false, "Only show log output for steps that failed")
+	cmd.Flags().BoolVar(&opts.Failures, "failures", false, "Show logs and annotations for failed steps, without needing --log --job")
+	cmd.Flags().IntVar(&opts.Tail, "tail", 20, "Number of log lines to show for each failed step with --failures")
+	cmdutil.AddJSONFlags(cmd, &opts.Exporter, viewFields)
 
 	return cmd
 }
@@ -167,6 +301,15 @@ func runView(opts *ViewOptions) error {
 
 	cs := opts.IO.ColorScheme()
 
+	if runID == "" && jobID == "" && !opts.Prompt {
+		opts.IO.StartProgressIndicator()
+		runID, err = getLatestRunID(client, repo, opts.Workflow, opts.Branch, opts.Event, opts.Status)
+		opts.IO.StopProgressIndicator()
+		if err != nil {
+			return fmt.Errorf("failed to find a matching run: %w", err)
+		}
+	}
+
 	if opts.Prompt {
 		// TODO arbitrary limit
 		opts.IO.StartProgressIndicator()
@@ -218,6 +361,20 @@ func runView(opts *ViewOptions) error {
 	opts.IO.StartProgressIndicator()
 
 	if opts.Log && selectedJob != nil {
+		if opts.Follow {
+			opts.IO.StopProgressIndicator()
+			finalJob, err := followJobLog(client, httpClient, opts.IO, repo, selectedJob, opts.Interval)
+			if err != nil {
+				return err
+			}
+
+			if opts.ExitStatus && shared.IsFailureState(finalJob.Conclusion) {
+				return cmdutil.SilentError
+			}
+
+			return nil
+		}
+
 		if selectedJob.Status != shared.Completed {
 			return fmt.Errorf("job %d is still in progress; logs will be available when it is complete", selectedJob.ID)
 		}
@@ -234,7 +391,15 @@ func runView(opts *ViewOptions) error {
 		}
 		defer opts.IO.StopPager()
 
-		if _, err := io.Copy(opts.IO.Out, r); err != nil {
+		if opts.Grep != "" {
+			re, err := regexp.Compile(opts.Grep)
+			if err != nil {
+				return fmt.Errorf("invalid --grep pattern: %w", err)
+			}
+			if err := copyMatchingLines(opts.IO.Out, r, re, opts.Context); err != nil {
+				return fmt.Errorf("failed to read log: %w", err)
+			}
+		} else if _, err := io.Copy(opts.IO.Out, r); err != nil {
 			return fmt.Errorf("failed to read log: %w", err)
 		}
 
@@ -246,6 +411,20 @@ func runView(opts *ViewOptions) error {
 	}
 
 	if opts.Log {
+		if opts.Follow {
+			opts.IO.StopProgressIndicator()
+			finalRun, err := followRunLog(client, httpClient, opts.IO, repo, run, opts.Interval)
+			if err != nil {
+				return err
+			}
+
+			if opts.ExitStatus && shared.IsFailureState(finalRun.Conclusion) {
+				return cmdutil.SilentError
+			}
+
+			return nil
+		}
+
 		if run.Status != shared.Completed {
 			return fmt.Errorf("run %d is still in progress; logs will be available when it is complete", run.ID)
 		}
@@ -254,14 +433,26 @@ func runView(opts *ViewOptions) error {
 		if err != nil {
 			return fmt.Errorf("failed to get run log: %w", err)
 		}
-		opts.IO.StopProgressIndicator()
 
 		runLog, err := readRunLog(runLogZip)
 		if err != nil {
 			return err
 		}
 
-		return displayRunLog(opts.IO, runLog)
+		if opts.FailedOnly && len(jobs) == 0 {
+			jobs, err = shared.GetJobs(client, repo, *run)
+			if err != nil {
+				return fmt.Errorf("failed to get jobs: %w", err)
+			}
+		}
+		opts.IO.StopProgressIndicator()
+
+		runLog, err = filterRunLog(runLog, jobs, opts.FilterJob, opts.FilterStep, opts.FailedOnly)
+		if err != nil {
+			return err
+		}
+
+		return displayRunLog(opts.IO, runLog, opts.Grep, opts.Context)
 	}
 
 	if selectedJob == nil && len(jobs) == 0 {
@@ -289,6 +480,7 @@ func runView(opts *ViewOptions) error {
 	}
 
 	var annotations []shared.Annotation
+	annotationsByJob := map[string][]shared.Annotation{}
 
 	var annotationErr error
 	var as []shared.Annotation
@@ -298,6 +490,7 @@ func runView(opts *ViewOptions) error {
 			break
 		}
 		annotations = append(annotations, as...)
+		annotationsByJob[job.Name] = as
 	}
 
 	opts.IO.StopProgressIndicator()
@@ -306,6 +499,19 @@ func runView(opts *ViewOptions) error {
 		return fmt.Errorf("failed to get annotations: %w", annotationErr)
 	}
 
+	if opts.Exporter != nil {
+		if selectedJob != nil {
+			return opts.Exporter.Write(opts.IO, &jobExport{job: selectedJob})
+		}
+		return opts.Exporter.Write(opts.IO, &runExport{
+			run:         run,
+			jobs:        jobs,
+			artifacts:   artifacts,
+			annotations: annotations,
+			prNumber:    number,
+		})
+	}
+
 	out := opts.IO.Out
 
 	ago := opts.Now().Sub(run.CreatedAt)
@@ -335,7 +541,32 @@ func runView(opts *ViewOptions) error {
 		fmt.Fprintln(out, shared.RenderJobs(cs, jobs, true))
 	}
 
-	if len(annotations) > 0 {
+	showFailures := opts.Failures || run.Conclusion == shared.Failure
+
+	failures := ""
+	if showFailures {
+		if run.Status != shared.Completed {
+			if opts.Failures {
+				failures = fmt.Sprintf("run %d is still in progress; failure details will be available when it is complete\n", run.ID)
+			}
+		} else {
+			opts.IO.StartProgressIndicator()
+			failures, err = renderFailures(cs, httpClient, repo, run.ID, jobs, annotationsByJob, opts.Tail)
+			opts.IO.StopProgressIndicator()
+			if err != nil {
+				return fmt.Errorf("failed to get failure logs: %w", err)
+			}
+		}
+	}
+
+	if failures != "" {
+		fmt.Fprintln(out)
+		fmt.Fprint(out, failures)
+	} else if len(annotations) > 0 {
+		// Either --failures/the failure conclusion didn't turn up anything
+		// step-level to report (e.g. a startup_failure with no steps), or
+		// this run didn't fail at all: fall back to the plain annotations
+		// list like before this mode existed.
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, cs.Bold("ANNOTATIONS"))
 		fmt.Fprintln(out, shared.RenderAnnotations(cs, annotations))
@@ -374,6 +605,95 @@ func runView(opts *ViewOptions) error {
 	return nil
 }
 
+// runExport is the --json representation of a run, together with the data
+// that isn't part of shared.Run itself: the resolved PR number, jobs,
+// artifacts, and aggregated annotations.
+type runExport struct {
+	run         *shared.Run
+	jobs        []shared.Job
+	artifacts   []shared.Artifact
+	annotations []shared.Annotation
+	prNumber    int
+}
+
+func (e *runExport) ExportData(fields []string) map[string]interface{} {
+	data, err := toExportMap(e.run)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	data["number"] = e.prNumber
+	// shared.Run's own JSON tags take priority if it ever grows a distinct
+	// workflow-identity field; only fall back to the run name (which is
+	// the workflow's name per the Actions API, e.g. "CI") when it doesn't.
+	if _, ok := data["workflow"]; !ok {
+		data["workflow"] = data["name"]
+	}
+
+	jobsData := make([]map[string]interface{}, 0, len(e.jobs))
+	for _, j := range e.jobs {
+		jobsData = append(jobsData, (&jobExport{job: &j}).ExportData(jobFields))
+	}
+	data["jobs"] = jobsData
+
+	artifactsData := make([]map[string]interface{}, 0, len(e.artifacts))
+	for _, a := range e.artifacts {
+		am, err := toExportMap(a)
+		if err != nil {
+			continue
+		}
+		artifactsData = append(artifactsData, am)
+	}
+	data["artifacts"] = artifactsData
+
+	annotationsData := make([]map[string]interface{}, 0, len(e.annotations))
+	for _, a := range e.annotations {
+		am, err := toExportMap(a)
+		if err != nil {
+			continue
+		}
+		annotationsData = append(annotationsData, am)
+	}
+	data["annotations"] = annotationsData
+
+	return filterExportFields(data, fields)
+}
+
+// jobExport is the --json representation of a single job, used both as the
+// "jobs" element of a run document and as the document for `--job --json`.
+type jobExport struct {
+	job *shared.Job
+}
+
+func (e *jobExport) ExportData(fields []string) map[string]interface{} {
+	data, err := toExportMap(e.job)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	return filterExportFields(data, fields)
+}
+
+func toExportMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func filterExportFields(data map[string]interface{}, fields []string) map[string]interface{} {
+	filtered := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if v, ok := data[f]; ok {
+			filtered[f] = v
+		}
+	}
+	return filtered
+}
+
 func getJob(client *api.Client, repo ghrepo.Interface, jobID string) (*shared.Job, error) {
 	path := fmt.Sprintf("repos/%s/actions/jobs/%s", ghrepo.FullName(repo), jobID)
 
@@ -386,6 +706,46 @@ func getJob(client *api.Client, repo ghrepo.Interface, jobID string) (*shared.Jo
 	return &result, nil
 }
 
+func getLatestRunID(client *api.Client, repo ghrepo.Interface, workflow, branch, event, status string) (string, error) {
+	path := fmt.Sprintf("repos/%s/actions/runs", ghrepo.FullName(repo))
+	if workflow != "" {
+		path = fmt.Sprintf("repos/%s/actions/workflows/%s/runs", ghrepo.FullName(repo), workflow)
+	}
+
+	q := url.Values{}
+	if branch != "" {
+		q.Set("branch", branch)
+	}
+	if event != "" {
+		q.Set("event", event)
+	}
+	if status != "" {
+		q.Set("status", status)
+	}
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	var result struct {
+		WorkflowRuns []shared.Run `json:"workflow_runs"`
+	}
+	err := client.REST(repo.RepoHost(), "GET", path, nil, &result)
+	if err != nil {
+		return "", err
+	}
+
+	if len(result.WorkflowRuns) == 0 {
+		return "", errors.New("could not find any runs matching the given filters")
+	}
+
+	runs := result.WorkflowRuns
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+
+	return strconv.Itoa(runs[0].ID), nil
+}
+
 func getLog(httpClient *http.Client, logURL string) (io.ReadCloser, error) {
 	req, err := http.NewRequest("GET", logURL, nil)
 	if err != nil {
@@ -515,7 +875,400 @@ func readZipFile(zf *zip.File) ([]byte, error) {
 	return ioutil.ReadAll(f)
 }
 
-func displayRunLog(io *iostreams.IOStreams, rl runLog) error {
+// followJobLog polls a single job's log until the job completes, printing only
+// the bytes that have not been emitted yet on each pass.
+func followJobLog(client *api.Client, httpClient *http.Client, io *iostreams.IOStreams, repo ghrepo.Interface, selectedJob *shared.Job, intervalSeconds int) (*shared.Job, error) {
+	interval := time.Duration(intervalSeconds) * time.Second
+	current := selectedJob
+	var offset int64
+	seenSteps := map[string]bool{}
+
+	for {
+		r, err := getJobLog(httpClient, repo, current.ID)
+		if err != nil {
+			if err.Error() != "log not found" {
+				return nil, err
+			}
+			// Logs aren't uploaded until a step finishes; fall back to
+			// reporting step completions from the job itself.
+			printStepProgress(io.Out, current.Name, current.Steps, seenSteps)
+		} else {
+			b, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(b)) > offset {
+				printLogTail(io.Out, current.Name, lastActiveStepName(current.Steps), b[offset:])
+				offset = int64(len(b))
+			}
+		}
+
+		if current.Status == shared.Completed {
+			return current, nil
+		}
+
+		time.Sleep(interval)
+
+		current, err = getJob(client, repo, strconv.Itoa(current.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job: %w", err)
+		}
+	}
+}
+
+// followRunLog polls every job of a run until the whole run completes,
+// tailing each job's log independently and flushing new output as it arrives.
+func followRunLog(client *api.Client, httpClient *http.Client, io *iostreams.IOStreams, repo ghrepo.Interface, run *shared.Run, intervalSeconds int) (*shared.Run, error) {
+	interval := time.Duration(intervalSeconds) * time.Second
+	offsets := map[int]int64{}
+	seenSteps := map[int]map[string]bool{}
+
+	for {
+		jobs, err := shared.GetJobs(client, repo, *run)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get jobs: %w", err)
+		}
+
+		allCompleted := true
+		for _, j := range jobs {
+			if j.Status != shared.Completed {
+				allCompleted = false
+			}
+
+			r, err := getJobLog(httpClient, repo, j.ID)
+			if err != nil {
+				if err.Error() != "log not found" {
+					return nil, err
+				}
+				// Logs aren't uploaded until a step finishes; fall back to
+				// reporting step completions from the job itself.
+				if seenSteps[j.ID] == nil {
+					seenSteps[j.ID] = map[string]bool{}
+				}
+				printStepProgress(io.Out, j.Name, j.Steps, seenSteps[j.ID])
+				continue
+			}
+			b, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return nil, err
+			}
+			if off := offsets[j.ID]; int64(len(b)) > off {
+				printLogTail(io.Out, j.Name, lastActiveStepName(j.Steps), b[off:])
+				offsets[j.ID] = int64(len(b))
+			}
+		}
+
+		// A run whose jobs are generated dynamically (e.g. a matrix
+		// computed by an earlier job) can have an empty or partial job
+		// list on early polls; only the run itself knows when no more
+		// jobs are coming, so require both checks before stopping.
+		if allCompleted && run.Status == shared.Completed {
+			return run, nil
+		}
+
+		time.Sleep(interval)
+
+		run, err = shared.GetRun(client, repo, strconv.Itoa(run.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get run: %w", err)
+		}
+	}
+}
+
+// printStepProgress prints one line per step that has newly completed since
+// the last call, as a substitute progress signal for when the job log
+// endpoint 404s because logs for an in-progress job aren't uploaded yet.
+// seen is mutated to record which step completions have already been shown.
+func printStepProgress(w io.Writer, jobName string, steps []shared.Step, seen map[string]bool) {
+	for _, s := range steps {
+		if s.Status != shared.Completed || seen[s.Name] {
+			continue
+		}
+		seen[s.Name] = true
+		fmt.Fprintf(w, "%s\t%s\t%s\n", jobName, s.Name, s.Conclusion)
+	}
+}
+
+// lastActiveStepName returns the name of the step whose output a freshly
+// fetched log tail most likely belongs to: the most recently completed step
+// if one has finished since the job started, otherwise the step currently
+// running. This is a closer approximation than always naming the step that
+// happens to be running at print time, which can have already moved on from
+// the step(s) that actually produced the new bytes.
+func lastActiveStepName(steps []shared.Step) string {
+	var lastCompleted string
+	for _, s := range steps {
+		if s.Status != shared.Completed {
+			break
+		}
+		lastCompleted = s.Name
+	}
+	if lastCompleted != "" {
+		return lastCompleted
+	}
+	return currentStepName(steps)
+}
+
+// currentStepName returns the name of the step a job is presently running,
+// falling back to the last step once every step has finished.
+func currentStepName(steps []shared.Step) string {
+	for _, s := range steps {
+		if s.Status != shared.Completed {
+			return s.Name
+		}
+	}
+	if len(steps) > 0 {
+		return steps[len(steps)-1].Name
+	}
+	return ""
+}
+
+func printLogTail(w io.Writer, jobName, stepName string, b []byte) {
+	prefix := fmt.Sprintf("%s\t%s\t", jobName, stepName)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		fmt.Fprintf(w, "%s%s\n", prefix, scanner.Text())
+	}
+}
+
+// filterRunLog narrows a runLog down to the jobs/steps matching jobGlob and
+// stepGlob, and further to only failing steps when failedOnly is set. jobs
+// is only consulted when failedOnly is set, to look up step conclusions.
+func filterRunLog(rl runLog, jobs []shared.Job, jobGlob, stepGlob string, failedOnly bool) (runLog, error) {
+	if jobGlob == "" && stepGlob == "" && !failedOnly {
+		return rl, nil
+	}
+
+	failedSteps := map[string]bool{}
+	if failedOnly {
+		for _, j := range jobs {
+			for _, s := range j.Steps {
+				if shared.IsFailureState(s.Conclusion) {
+					failedSteps[j.Name+"/"+s.Name] = true
+				}
+			}
+		}
+	}
+
+	filtered := make(runLog)
+	for name, j := range rl {
+		if jobGlob != "" {
+			matched, err := filepath.Match(jobGlob, name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --filter-job pattern: %w", err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		var steps []step
+		for _, st := range j.steps {
+			if stepGlob != "" {
+				matched, err := filepath.Match(stepGlob, st.name)
+				if err != nil {
+					return nil, fmt.Errorf("invalid --filter-step pattern: %w", err)
+				}
+				if !matched {
+					continue
+				}
+			}
+			if failedOnly && !failedSteps[name+"/"+st.name] {
+				continue
+			}
+			steps = append(steps, st)
+		}
+
+		if len(steps) == 0 {
+			continue
+		}
+
+		filtered[name] = &job{name: j.name, steps: steps}
+	}
+
+	return filtered, nil
+}
+
+// renderFailures builds a "JOB / STEP" section for every failed step in
+// jobs: the heading, then that job's annotations, then the last tailLines
+// lines of the step's log, with a ±3 line excerpt anchored on any
+// annotation whose message appears in the log. It returns "" if jobs has no
+// step-level failures to report (e.g. a startup_failure with no steps),
+// leaving it to the caller to fall back to something else.
+func renderFailures(cs *iostreams.ColorScheme, httpClient *http.Client, repo ghrepo.Interface, runID int, jobs []shared.Job, annotationsByJob map[string][]shared.Annotation, tailLines int) (string, error) {
+	type failedStep struct {
+		job  shared.Job
+		step shared.Step
+	}
+
+	var failed []failedStep
+	for _, j := range jobs {
+		for _, st := range j.Steps {
+			if shared.IsFailureState(st.Conclusion) {
+				failed = append(failed, failedStep{job: j, step: st})
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return "", nil
+	}
+
+	runLogZip, err := getRunLog(httpClient, repo, runID)
+	if err != nil {
+		return "", err
+	}
+	rl, err := readRunLog(runLogZip)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for i, f := range failed {
+		if i > 0 {
+			fmt.Fprintln(&out)
+		}
+		fmt.Fprintf(&out, "%s %s\n", cs.FailureIcon(), cs.Bold(fmt.Sprintf("%s / %s", f.job.Name, f.step.Name)))
+
+		if as := annotationsByJob[f.job.Name]; len(as) > 0 {
+			fmt.Fprintln(&out)
+			fmt.Fprintln(&out, cs.Bold("ANNOTATIONS"))
+			fmt.Fprintln(&out, shared.RenderAnnotations(cs, as))
+		}
+
+		stepLog, ok := findStepLog(rl, f.job.Name, f.step.Name)
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintln(&out)
+		printLastLines(&out, stepLog, tailLines)
+
+		for _, a := range annotationsByJob[f.job.Name] {
+			if excerpt := annotationExcerpt(stepLog, a.Message, 3); excerpt != "" {
+				fmt.Fprintln(&out)
+				fmt.Fprint(&out, excerpt)
+			}
+		}
+	}
+
+	return out.String(), nil
+}
+
+// findStepLog returns the log text for the named step within the named job,
+// if the run log zip contained one.
+func findStepLog(rl runLog, jobName, stepName string) (string, bool) {
+	j, ok := rl[jobName]
+	if !ok {
+		return "", false
+	}
+	for _, st := range j.steps {
+		if st.name == stepName {
+			return st.logs, true
+		}
+	}
+	return "", false
+}
+
+// printLastLines writes the last n lines of logs to w.
+func printLastLines(w io.Writer, logs string, n int) {
+	lines := strings.Split(strings.TrimRight(logs, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for _, l := range lines {
+		fmt.Fprintln(w, l)
+	}
+}
+
+// annotationExcerpt returns the line in logs containing message, along with
+// contextLines of surrounding lines, or "" if message is empty or not found.
+func annotationExcerpt(logs, message string, contextLines int) string {
+	if message == "" {
+		return ""
+	}
+
+	lines := strings.Split(logs, "\n")
+	for i, l := range lines {
+		if !strings.Contains(l, message) {
+			continue
+		}
+
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i + contextLines
+		if end >= len(lines) {
+			end = len(lines) - 1
+		}
+
+		return strings.Join(lines[start:end+1], "\n") + "\n"
+	}
+
+	return ""
+}
+
+// copyMatchingLines scans r and writes to w only the lines matching re, along
+// with contextLines of surrounding context, deduplicating overlapping windows.
+func copyMatchingLines(w io.Writer, r io.Reader, re *regexp.Regexp, contextLines int) error {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, i := range matchingLineIndexes(lines, re, contextLines) {
+		fmt.Fprintln(w, lines[i])
+	}
+
+	return nil
+}
+
+// matchingLineIndexes returns, in order and without duplicates, the indexes
+// of lines matching re together with contextLines of surrounding context.
+func matchingLineIndexes(lines []string, re *regexp.Regexp, contextLines int) []int {
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if !re.MatchString(l) {
+			continue
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i + contextLines
+		if end >= len(lines) {
+			end = len(lines) - 1
+		}
+		for j := start; j <= end; j++ {
+			keep[j] = true
+		}
+	}
+
+	var indexes []int
+	for i, k := range keep {
+		if k {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func displayRunLog(io *iostreams.IOStreams, rl runLog, grep string, contextLines int) error {
+	var re *regexp.Regexp
+	if grep != "" {
+		var err error
+		re, err = regexp.Compile(grep)
+		if err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+
 	err := io.StartPager()
 	if err != nil {
 		return err
@@ -536,9 +1289,22 @@ func displayRunLog(io *iostreams.IOStreams, rl runLog) error {
 		})
 		for _, step := range steps {
 			prefix := fmt.Sprintf("%s\t%s\t", job.name, step.name)
+
+			if re == nil {
+				scanner := bufio.NewScanner(strings.NewReader(step.logs))
+				for scanner.Scan() {
+					fmt.Fprintf(io.Out, "%s%s\n", prefix, scanner.Text())
+				}
+				continue
+			}
+
+			var lines []string
 			scanner := bufio.NewScanner(strings.NewReader(step.logs))
 			for scanner.Scan() {
-				fmt.Fprintf(io.Out, "%s%s\n", prefix, scanner.Text())
+				lines = append(lines, scanner.Text())
+			}
+			for _, i := range matchingLineIndexes(lines, re, contextLines) {
+				fmt.Fprintf(io.Out, "%s%s\n", prefix, lines[i])
 			}
 		}
 	}
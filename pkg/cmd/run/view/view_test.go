@@ -0,0 +1,259 @@
+package view
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/cli/cli/api"
+	"github.com/cli/cli/internal/ghrepo"
+	"github.com/cli/cli/pkg/cmd/run/shared"
+	"github.com/cli/cli/pkg/httpmock"
+	"github.com/cli/cli/pkg/iostreams"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchingLineIndexes(t *testing.T) {
+	tests := []struct {
+		name         string
+		lines        []string
+		pattern      string
+		contextLines int
+		want         []int
+	}{
+		{
+			name:    "no match",
+			lines:   []string{"a", "b", "c"},
+			pattern: "nope",
+			want:    nil,
+		},
+		{
+			name:    "single match, no context",
+			lines:   []string{"a", "boom", "c"},
+			pattern: "boom",
+			want:    []int{1},
+		},
+		{
+			name:         "context is clamped at the edges",
+			lines:        []string{"boom", "b", "c"},
+			pattern:      "boom",
+			contextLines: 2,
+			want:         []int{0, 1, 2},
+		},
+		{
+			name:         "overlapping windows are deduplicated and kept in order",
+			lines:        []string{"boom1", "b", "boom2", "d"},
+			pattern:      "boom",
+			contextLines: 1,
+			want:         []int{0, 1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := regexp.MustCompile(tt.pattern)
+			got := matchingLineIndexes(tt.lines, re, tt.contextLines)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterRunLog(t *testing.T) {
+	rl := runLog{
+		"build": &job{name: "build", steps: []step{
+			{order: 1, name: "checkout", logs: "ok"},
+			{order: 2, name: "test", logs: "fail"},
+		}},
+		"deploy": &job{name: "deploy", steps: []step{
+			{order: 1, name: "push", logs: "ok"},
+		}},
+	}
+	jobs := []shared.Job{
+		{Name: "build", Steps: []shared.Step{
+			{Name: "checkout", Conclusion: shared.Success},
+			{Name: "test", Conclusion: shared.Failure},
+		}},
+		{Name: "deploy", Steps: []shared.Step{
+			{Name: "push", Conclusion: shared.Success},
+		}},
+	}
+
+	t.Run("no filters returns the log unchanged", func(t *testing.T) {
+		got, err := filterRunLog(rl, jobs, "", "", false)
+		require.NoError(t, err)
+		assert.Equal(t, rl, got)
+	})
+
+	t.Run("job glob keeps only matching jobs", func(t *testing.T) {
+		got, err := filterRunLog(rl, jobs, "dep*", "", false)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Contains(t, got, "deploy")
+	})
+
+	t.Run("step glob keeps only matching steps, dropping jobs left empty", func(t *testing.T) {
+		got, err := filterRunLog(rl, jobs, "", "push", false)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Contains(t, got, "deploy")
+	})
+
+	t.Run("failed-only keeps only failing steps", func(t *testing.T) {
+		got, err := filterRunLog(rl, jobs, "", "", true)
+		require.NoError(t, err)
+		require.Contains(t, got, "build")
+		assert.Len(t, got["build"].steps, 1)
+		assert.Equal(t, "test", got["build"].steps[0].name)
+		assert.NotContains(t, got, "deploy")
+	})
+
+	t.Run("invalid job glob errors", func(t *testing.T) {
+		_, err := filterRunLog(rl, jobs, "[", "", false)
+		assert.Error(t, err)
+	})
+}
+
+func TestRunExportExportData(t *testing.T) {
+	run := &shared.Run{
+		ID:     123,
+		Name:   "CI",
+		Status: shared.Completed,
+	}
+	export := &runExport{run: run, prNumber: 42}
+
+	data := export.ExportData([]string{"name", "workflow", "number"})
+	assert.Equal(t, "CI", data["name"])
+	assert.Equal(t, "CI", data["workflow"], "workflow falls back to the run's name when shared.Run doesn't have its own")
+	assert.Equal(t, 42, data["number"])
+}
+
+func TestJobExportExportData(t *testing.T) {
+	job := &shared.Job{
+		ID:     1,
+		Name:   "build",
+		Status: shared.Completed,
+		Steps: []shared.Step{
+			{Name: "checkout"},
+		},
+	}
+	export := &jobExport{job: job}
+
+	data := export.ExportData([]string{"name", "steps"})
+	assert.Equal(t, "build", data["name"])
+	assert.NotNil(t, data["steps"], "steps must be exportable for `gh run view --job --json steps`")
+	assert.NotContains(t, data, "workflow", "job documents don't have a workflow field")
+}
+
+func TestRenderFailuresNoFailedSteps(t *testing.T) {
+	jobs := []shared.Job{
+		{Name: "setup", Conclusion: shared.Failure, Steps: nil},
+	}
+	cs := iostreams.NewColorScheme(false, false, false)
+
+	got, err := renderFailures(cs, &http.Client{}, nil, 1, jobs, nil, 20)
+	require.NoError(t, err)
+	assert.Empty(t, got, "a job-level failure with no steps has nothing to render; caller falls back to ANNOTATIONS")
+}
+
+func TestGetLatestRunID(t *testing.T) {
+	reg := &httpmock.Registry{}
+	defer reg.Verify(t)
+	reg.Register(
+		httpmock.QueryMatcher("GET", "repos/OWNER/REPO/actions/workflows/ci.yml/runs", url.Values{
+			"branch": []string{"main"},
+			"event":  []string{"push"},
+			"status": []string{"completed"},
+		}),
+		httpmock.JSONResponse(map[string]interface{}{
+			"workflow_runs": []map[string]interface{}{
+				{"id": 1, "created_at": "2021-01-01T00:00:00Z"},
+				{"id": 2, "created_at": "2021-02-01T00:00:00Z"},
+			},
+		}),
+	)
+
+	httpClient := &http.Client{Transport: reg}
+	client := api.NewClientFromHTTP(httpClient)
+	repo, _ := ghrepo.FromFullName("OWNER/REPO")
+
+	got, err := getLatestRunID(client, repo, "ci.yml", "main", "push", "completed")
+	require.NoError(t, err)
+	assert.Equal(t, "2", got, "expected the most recently created run")
+}
+
+func TestGetLatestRunIDNoMatches(t *testing.T) {
+	reg := &httpmock.Registry{}
+	defer reg.Verify(t)
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/actions/runs"),
+		httpmock.JSONResponse(map[string]interface{}{"workflow_runs": []interface{}{}}),
+	)
+
+	httpClient := &http.Client{Transport: reg}
+	client := api.NewClientFromHTTP(httpClient)
+	repo, _ := ghrepo.FromFullName("OWNER/REPO")
+
+	_, err := getLatestRunID(client, repo, "", "", "", "")
+	assert.Error(t, err)
+}
+
+// TestFollowRunLogWaitsForDynamicJobs covers a run whose job list grows
+// across polls (e.g. a matrix spawned by an earlier job): the first poll
+// reports every job it knows about as Completed, but the run itself is
+// still in_progress, and a later poll reveals an additional job.
+func TestFollowRunLogWaitsForDynamicJobs(t *testing.T) {
+	reg := &httpmock.Registry{}
+	defer reg.Verify(t)
+
+	// First poll: only the setup job exists, and it already finished.
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/actions/runs/1/jobs"),
+		httpmock.JSONResponse(map[string]interface{}{
+			"jobs": []map[string]interface{}{
+				{"id": 1, "name": "setup", "status": "completed", "conclusion": "success"},
+			},
+		}),
+	)
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/actions/jobs/1/logs"),
+		httpmock.StringResponse("setup done\n"),
+	)
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/actions/runs/1"),
+		httpmock.JSONResponse(map[string]interface{}{"id": 1, "status": "completed"}),
+	)
+
+	// Second poll: the matrix job generated by "setup" now exists too, and
+	// the run-refresh above has reported the run itself as completed, so
+	// this is the poll that should end the follow loop.
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/actions/runs/1/jobs"),
+		httpmock.JSONResponse(map[string]interface{}{
+			"jobs": []map[string]interface{}{
+				{"id": 1, "name": "setup", "status": "completed", "conclusion": "success"},
+				{"id": 2, "name": "matrix-a", "status": "completed", "conclusion": "success"},
+			},
+		}),
+	)
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/actions/jobs/1/logs"),
+		httpmock.StringResponse("setup done\n"),
+	)
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/actions/jobs/2/logs"),
+		httpmock.StringResponse("matrix done\n"),
+	)
+
+	httpClient := &http.Client{Transport: reg}
+	client := api.NewClientFromHTTP(httpClient)
+	repo, _ := ghrepo.FromFullName("OWNER/REPO")
+	ios, _, stdout, _ := iostreams.Test()
+
+	run := &shared.Run{ID: 1, Status: shared.InProgress}
+	final, err := followRunLog(client, httpClient, ios, repo, run, 0)
+	require.NoError(t, err)
+	assert.Equal(t, shared.Completed, final.Status)
+	assert.Contains(t, stdout.String(), "matrix-a")
+}